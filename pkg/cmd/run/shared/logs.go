@@ -0,0 +1,123 @@
+package shared
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/cli/cli/internal/ghinstance"
+	"github.com/cli/cli/internal/ghrepo"
+)
+
+// LogTailer incrementally fetches the log output of in-progress jobs. For
+// each job it remembers the last byte offset it read, so repeated polls
+// only request what's new, and keeps a rolling buffer of the most recent
+// lines so a full-screen redraw can keep reprinting the same tail without
+// re-fetching the whole log every tick.
+type LogTailer struct {
+	maxLines int
+
+	mu      sync.Mutex
+	offsets map[int64]int64
+	tails   map[int64][]string
+}
+
+func NewLogTailer(maxLines int) *LogTailer {
+	return &LogTailer{
+		maxLines: maxLines,
+		offsets:  map[int64]int64{},
+		tails:    map[int64][]string{},
+	}
+}
+
+// Tail fetches whatever log bytes have been appended to job's log since
+// the last call for that job ID, folds any new lines into the rolling
+// buffer for job, and returns the buffer's current contents (at most
+// maxLines entries).
+func (t *LogTailer) Tail(httpClient *http.Client, repo ghrepo.Interface, job Job) ([]string, error) {
+	offset := t.offsetFor(job.ID)
+
+	url := fmt.Sprintf("%srepos/%s/%s/actions/jobs/%d/logs",
+		ghinstance.RESTPrefix(repo.RepoHost()), repo.RepoOwner(), repo.RepoName(), job.ID)
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusPartialContent:
+		body, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return nil, err
+		}
+		t.advance(job.ID, offset+int64(len(body)), splitLogLines(body))
+	case http.StatusRequestedRangeNotSatisfiable:
+		// nothing new since our last read
+	default:
+		return nil, fmt.Errorf("failed to fetch job logs: %s", resp.Status)
+	}
+
+	return t.tailFor(job.ID), nil
+}
+
+// Forget drops the remembered offset and buffer for a job, e.g. once it
+// has finished and its tail has been collapsed out of the display.
+func (t *LogTailer) Forget(jobID int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.offsets, jobID)
+	delete(t.tails, jobID)
+}
+
+func (t *LogTailer) offsetFor(jobID int64) int64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.offsets[jobID]
+}
+
+func (t *LogTailer) tailFor(jobID int64) []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.tails[jobID]
+}
+
+func (t *LogTailer) advance(jobID int64, offset int64, newLines []string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.offsets[jobID] = offset
+
+	lines := append(t.tails[jobID], newLines...)
+	if len(lines) > t.maxLines {
+		lines = lines[len(lines)-t.maxLines:]
+	}
+	t.tails[jobID] = lines
+}
+
+// splitLogLines splits buf into lines, stripping the RFC3339 timestamp
+// prefix GitHub adds to each log line.
+func splitLogLines(buf []byte) []string {
+	scanner := bufio.NewScanner(bytes.NewReader(buf))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var lines []string
+	for scanner.Scan() {
+		line := scanner.Text()
+		if idx := strings.Index(line, "Z "); idx > 0 && idx < 40 {
+			line = line[idx+2:]
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
@@ -0,0 +1,83 @@
+package shared
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/cli/cli/internal/ghrepo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rewriteHostTransport points every request at srv regardless of the
+// scheme/host LogTailer built the request with, so the test can exercise
+// the real URL-construction code path without actually hitting a host.
+type rewriteHostTransport struct {
+	srv *httptest.Server
+}
+
+func (t *rewriteHostTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	target, err := url.Parse(t.srv.URL)
+	if err != nil {
+		return nil, err
+	}
+	req.URL.Scheme = target.Scheme
+	req.URL.Host = target.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestLogTailer_Tail(t *testing.T) {
+	var ranges []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ranges = append(ranges, r.Header.Get("Range"))
+		switch len(ranges) {
+		case 1:
+			_, _ = w.Write([]byte("2021-01-01T00:00:00.0000000Z line one\n2021-01-01T00:00:01.0000000Z line two\n"))
+		default:
+			w.WriteHeader(http.StatusRequestedRangeNotSatisfiable)
+		}
+	}))
+	defer srv.Close()
+
+	httpClient := &http.Client{Transport: &rewriteHostTransport{srv: srv}}
+	repo := ghrepo.New("OWNER", "REPO")
+	job := Job{ID: 123}
+
+	tailer := NewLogTailer(10)
+
+	lines, err := tailer.Tail(httpClient, repo, job)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"line one", "line two"}, lines)
+
+	lines, err = tailer.Tail(httpClient, repo, job)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"line one", "line two"}, lines, "an unsatisfiable range means nothing new, so the tail should be unchanged")
+
+	require.Len(t, ranges, 2)
+	assert.Equal(t, "bytes=0-", ranges[0])
+	assert.NotEqual(t, "bytes=0-", ranges[1], "the second request should resume from the offset the first call advanced to")
+}
+
+func TestLogTailer_ForgetDropsState(t *testing.T) {
+	tailer := NewLogTailer(10)
+	tailer.advance(1, 42, []string{"a"})
+
+	tailer.Forget(1)
+
+	assert.Equal(t, int64(0), tailer.offsetFor(1))
+	assert.Empty(t, tailer.tailFor(1))
+}
+
+func TestLogTailer_MaxLines(t *testing.T) {
+	tailer := NewLogTailer(2)
+	tailer.advance(1, 10, []string{"a", "b", "c"})
+	assert.Equal(t, []string{"b", "c"}, tailer.tailFor(1))
+}
+
+func TestSplitLogLines_StripsTimestampPrefix(t *testing.T) {
+	buf := []byte("2021-01-01T00:00:00.0000000Z hello\nno timestamp here\n")
+	lines := splitLogLines(buf)
+	assert.Equal(t, []string{"hello", "no timestamp here"}, lines)
+}
@@ -0,0 +1,212 @@
+package shared
+
+import (
+	"bytes"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ConditionalCacheTransport wraps an http.RoundTripper and remembers the
+// ETag/Last-Modified of the last response seen for a given URL, attaching
+// If-None-Match/If-Modified-Since on the next request to that URL. A 304
+// response is rewritten into the last 200 we saw, so callers that only
+// speak "decode this body" don't need to know caching happened at all.
+// Conditional requests like these don't count against GitHub's primary
+// rate limit, which matters a lot for a loop that's polling every couple
+// of seconds.
+type ConditionalCacheTransport struct {
+	Base http.RoundTripper
+
+	mu         sync.Mutex
+	entries    map[string]*cacheEntry
+	retryAfter time.Time
+}
+
+type cacheEntry struct {
+	etag         string
+	lastModified string
+	status       int
+	header       http.Header
+	body         []byte
+}
+
+func NewConditionalCacheTransport(base http.RoundTripper) *ConditionalCacheTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &ConditionalCacheTransport{Base: base, entries: map[string]*cacheEntry{}}
+}
+
+// waitForRetryAfter blocks until any backoff noted by a previous response
+// has elapsed, so a rate-limit signal delays the next outgoing request
+// instead of the response that reported it.
+func (t *ConditionalCacheTransport) waitForRetryAfter() {
+	t.mu.Lock()
+	wait := time.Until(t.retryAfter)
+	t.mu.Unlock()
+
+	if wait > 0 {
+		time.Sleep(wait)
+	}
+}
+
+func (t *ConditionalCacheTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.waitForRetryAfter()
+
+	// Requests carrying a Range header (the log tailer's incremental
+	// fetches) address a byte window, not "the resource at this URL" — the
+	// cache is keyed on URL alone, so caching or conditionally-requesting
+	// them would replay a stale range's body under a different range's
+	// offset. Leave those uncached and let them through as plain GETs.
+	if req.Method != http.MethodGet || req.Header.Get("Range") != "" {
+		return t.Base.RoundTrip(req)
+	}
+
+	key := req.URL.String()
+
+	t.mu.Lock()
+	cached, ok := t.entries[key]
+	t.mu.Unlock()
+
+	if ok {
+		if cached.etag != "" {
+			req.Header.Set("If-None-Match", cached.etag)
+		}
+		if cached.lastModified != "" {
+			req.Header.Set("If-Modified-Since", cached.lastModified)
+		}
+	}
+
+	resp, err := t.Base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Note the delay for the *next* request rather than sleeping here:
+	// the response above already happened and the caller is waiting on
+	// it, so blocking before returning it would just hold up data we
+	// already have.
+	isSecondaryLimit := resp.StatusCode == http.StatusForbidden && resp.Header.Get("Retry-After") != ""
+	if delay := RateLimitDelay(resp.Header, isSecondaryLimit); delay > 0 {
+		t.mu.Lock()
+		t.retryAfter = time.Now().Add(delay)
+		t.mu.Unlock()
+	}
+
+	if resp.StatusCode == http.StatusNotModified && ok {
+		resp.Body.Close()
+		resp.StatusCode = cached.status
+		resp.Status = strconv.Itoa(cached.status)
+		resp.Header = cached.header.Clone()
+		resp.Body = ioutil.NopCloser(bytes.NewReader(cached.body))
+		return resp, nil
+	}
+
+	if resp.StatusCode == http.StatusOK {
+		body, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+		etag := resp.Header.Get("ETag")
+		lastModified := resp.Header.Get("Last-Modified")
+		if etag != "" || lastModified != "" {
+			t.mu.Lock()
+			t.entries[key] = &cacheEntry{
+				etag:         etag,
+				lastModified: lastModified,
+				status:       resp.StatusCode,
+				header:       resp.Header.Clone(),
+				body:         body,
+			}
+			t.mu.Unlock()
+		}
+	}
+
+	return resp, nil
+}
+
+// AdaptivePoller decides how long to wait between polls of a run that
+// isn't changing. It starts at the caller's requested interval, backs off
+// up to maxInterval after several unchanged ticks, and snaps back down as
+// soon as something changes. It does not look at rate limit headers;
+// that backoff is applied per request by ConditionalCacheTransport using
+// RateLimitDelay.
+type AdaptivePoller struct {
+	base    time.Duration
+	max     time.Duration
+	stale   int
+	onlyFor time.Duration
+}
+
+// NewAdaptivePoller builds a poller that starts at interval and backs off
+// to at most maxInterval after staleAfter consecutive unchanged ticks.
+func NewAdaptivePoller(interval, maxInterval time.Duration, staleAfter int) *AdaptivePoller {
+	return &AdaptivePoller{base: interval, max: maxInterval, stale: staleAfter}
+}
+
+// Next reports how long to wait before the next poll. changed indicates
+// whether the last poll observed a state transition (job started,
+// finished, new annotation, etc).
+func (p *AdaptivePoller) Next(changed bool) time.Duration {
+	if changed {
+		p.onlyFor = 0
+		return p.base
+	}
+
+	p.onlyFor += p.base
+	if p.onlyFor <= p.base*time.Duration(p.stale) {
+		return p.base
+	}
+
+	next := withJitter(p.base * 2)
+	if next > p.max {
+		next = p.max
+	}
+	return next
+}
+
+// RateLimitDelay returns how long to sleep in response to a response's
+// rate-limit headers, or zero if no backoff is warranted. resp403 should
+// be true if the response was a secondary-rate-limit 403. A response that
+// carries no rate-limit headers at all (e.g. the blob-storage host job
+// log downloads redirect to) is "no information", not "assume the worst"
+// — it returns zero unless resp403 says otherwise.
+func RateLimitDelay(header http.Header, resp403 bool) time.Duration {
+	remainingHeader := header.Get("X-RateLimit-Remaining")
+	if remainingHeader == "" && !resp403 {
+		return 0
+	}
+
+	remaining, _ := strconv.Atoi(remainingHeader)
+	if !resp403 && remaining > 10 {
+		return 0
+	}
+
+	resetHeader := header.Get("X-RateLimit-Reset")
+	if resetHeader == "" {
+		return withJitter(30 * time.Second)
+	}
+
+	reset, _ := strconv.ParseInt(resetHeader, 10, 64)
+	d := time.Until(time.Unix(reset, 0))
+	if d < 0 {
+		return 0
+	}
+	return withJitter(d)
+}
+
+func withJitter(d time.Duration) time.Duration {
+	jitter := time.Duration(rand.Int63n(int64(d)/4 + 1))
+	if next := d + jitter; next >= d {
+		return next
+	}
+	// d + jitter overflowed; d alone is already a very long wait.
+	return d
+}
@@ -0,0 +1,158 @@
+package shared
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdaptivePoller_BacksOffThenResetsOnChange(t *testing.T) {
+	p := NewAdaptivePoller(1*time.Second, 8*time.Second, 2)
+
+	assert.Equal(t, 1*time.Second, p.Next(true))
+	assert.Equal(t, 1*time.Second, p.Next(false))
+	assert.Equal(t, 1*time.Second, p.Next(false))
+	assert.Greater(t, p.Next(false), 1*time.Second)
+	assert.Equal(t, 1*time.Second, p.Next(true))
+}
+
+func TestRateLimitDelay(t *testing.T) {
+	tests := []struct {
+		name     string
+		header   func() http.Header
+		resp403  bool
+		wantZero bool
+	}{
+		{
+			name:     "no rate-limit headers at all is not treated as rate-limited",
+			header:   func() http.Header { return make(http.Header) },
+			wantZero: true,
+		},
+		{
+			name: "plenty of quota remaining",
+			header: func() http.Header {
+				h := make(http.Header)
+				h.Set("X-RateLimit-Remaining", "500")
+				return h
+			},
+			wantZero: true,
+		},
+		{
+			name: "nearly exhausted quota waits for reset",
+			header: func() http.Header {
+				h := make(http.Header)
+				h.Set("X-RateLimit-Remaining", "1")
+				h.Set("X-RateLimit-Reset", "9999999999")
+				return h
+			},
+			wantZero: false,
+		},
+		{
+			name:     "secondary rate limit with no headers still backs off",
+			header:   func() http.Header { return make(http.Header) },
+			resp403:  true,
+			wantZero: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := RateLimitDelay(tt.header(), tt.resp403)
+			if tt.wantZero {
+				assert.Zero(t, d)
+			} else {
+				assert.Greater(t, d, time.Duration(0))
+			}
+		})
+	}
+}
+
+func TestConditionalCacheTransport_ReturnsCachedBodyOn304(t *testing.T) {
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("hello"))
+	}))
+	defer srv.Close()
+
+	client := &http.Client{Transport: NewConditionalCacheTransport(http.DefaultTransport)}
+
+	first, err := client.Get(srv.URL)
+	require.NoError(t, err)
+	requireBody(t, first, "hello")
+
+	second, err := client.Get(srv.URL)
+	require.NoError(t, err)
+	requireBody(t, second, "hello")
+
+	assert.Equal(t, 2, requests, "the second request should have hit the server as a conditional GET")
+}
+
+func TestConditionalCacheTransport_DelaysNextRequestNotTheCurrentOne(t *testing.T) {
+	// X-RateLimit-Reset has whole-second resolution, so pick a reset far
+	// enough ahead that truncating to seconds still leaves it in the future.
+	reset := time.Now().Add(1500 * time.Millisecond).Unix()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Remaining", "1")
+		w.Header().Set("X-RateLimit-Reset", fmt.Sprintf("%d", reset))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	client := &http.Client{Transport: NewConditionalCacheTransport(http.DefaultTransport)}
+
+	start := time.Now()
+	_, err := client.Get(srv.URL)
+	require.NoError(t, err)
+	assert.Less(t, time.Since(start), 50*time.Millisecond, "the response that reported the rate limit must not itself be delayed")
+
+	start = time.Now()
+	_, err = client.Get(srv.URL)
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond, "the next request should wait out the reported backoff")
+}
+
+func TestConditionalCacheTransport_SkipsRangeRequests(t *testing.T) {
+	var ranges []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ranges = append(ranges, r.Header.Get("Range"))
+		w.Header().Set("ETag", `"v1"`)
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("chunk"))
+	}))
+	defer srv.Close()
+
+	client := &http.Client{Transport: NewConditionalCacheTransport(http.DefaultTransport)}
+
+	for _, rangeHeader := range []string{"bytes=0-", "bytes=5-"} {
+		req, err := http.NewRequest(http.MethodGet, srv.URL, nil)
+		require.NoError(t, err)
+		req.Header.Set("Range", rangeHeader)
+
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		requireBody(t, resp, "chunk")
+	}
+
+	assert.Equal(t, []string{"bytes=0-", "bytes=5-"}, ranges, "a later Range request must not carry If-None-Match from an earlier, differently-ranged request")
+}
+
+func requireBody(t *testing.T, resp *http.Response, want string) {
+	t.Helper()
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, want, string(body))
+}
@@ -0,0 +1,273 @@
+package watch
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/cli/cli/api"
+	"github.com/cli/cli/internal/ghrepo"
+	"github.com/cli/cli/pkg/cmd/run/shared"
+	"github.com/cli/cli/pkg/cmdutil"
+	"github.com/cli/cli/pkg/iostreams"
+	"github.com/cli/cli/utils"
+)
+
+// dashboardConcurrency bounds how many runs' jobs/annotations we fetch at
+// once, so watching a hundred active runs doesn't serialize a hundred
+// round-trips per tick.
+const dashboardConcurrency = 8
+
+// maxConsecutiveFetchFailures is how many ticks in a row a tracked run's
+// refetch can fail before we give up on it. A single failed GET just
+// reuses last tick's runDetail so a network blip can't be mistaken for
+// the run having completed; only more than this many in a row drops it
+// for real.
+const maxConsecutiveFetchFailures = 5
+
+// runDetail is everything the dashboard table needs about one matched run.
+type runDetail struct {
+	run      *shared.Run
+	prNumber string
+	lastJob  string
+}
+
+// runState is the bit of a run's identity we diff tick-to-tick to decide
+// whether anything actually changed.
+type runState struct {
+	status     shared.Status
+	conclusion shared.Conclusion
+}
+
+// watchDashboard is the `--all`/`--workflow`/`--branch` entry point: it
+// watches every run matching filter at once, rendering a compact table
+// that drops a run as soon as it completes.
+func watchDashboard(ctx context.Context, opts *WatchOptions, client *api.Client, repo ghrepo.Interface, filter func(shared.Run) bool) error {
+	cs := opts.IO.ColorScheme()
+	out := opts.IO.Out
+
+	setupScreen(opts)
+
+	poller := shared.NewAdaptivePoller(time.Duration(opts.Interval)*time.Second, maxPollInterval, staleTicksBeforeBackoff)
+
+	anyFailed := false
+	prevState := map[int64]runState{}
+	tracked := map[int64]bool{}
+	lastDetail := map[int64]*runDetail{}
+	failures := map[int64]int{}
+	tableLines := 0
+
+	for {
+		runs, err := shared.GetRunsWithFilter(client, repo, 50, func(run shared.Run) bool {
+			return run.Status != shared.Completed && filter(run)
+		})
+		if err != nil {
+			restoreTerminal(opts.IO)
+			return fmt.Errorf("failed to get runs: %w", err)
+		}
+
+		// Once a run is matched it stays tracked by ID until we've observed
+		// it Completed, even after it drops out of the "still active" list
+		// above — otherwise a run that finishes between this list call and
+		// its own completion would just vanish with no summary line,
+		// notification, or --exit-status accounting.
+		for _, run := range runs {
+			tracked[run.ID] = true
+		}
+
+		ids := make([]int64, 0, len(tracked))
+		for id := range tracked {
+			ids = append(ids, id)
+		}
+
+		fetched := fetchRunDetails(opts.IO, client, repo, ids)
+
+		// A run whose refetch errored this tick isn't necessarily done —
+		// it's just unknown. Fall back to what we last saw for it rather
+		// than dropping it, and only give up for real after enough
+		// consecutive failures that it looks like more than a blip.
+		details := make([]*runDetail, 0, len(ids))
+		for _, id := range ids {
+			if d := fetched[id]; d != nil {
+				lastDetail[id] = d
+				failures[id] = 0
+				details = append(details, d)
+				continue
+			}
+
+			failures[id]++
+			if failures[id] > maxConsecutiveFetchFailures {
+				delete(tracked, id)
+				delete(lastDetail, id)
+				delete(failures, id)
+				continue
+			}
+			if d := lastDetail[id]; d != nil {
+				details = append(details, d)
+			}
+		}
+
+		// Move the cursor back up to the top of the table we printed last
+		// tick and clear everything from there down, so completed-run
+		// summaries printed on earlier ticks stay in the scrollback.
+		if tableLines > 0 {
+			fmt.Fprintf(out, "\x1b[%dA\x1b[J", tableLines)
+		}
+
+		changed := false
+		nextState := make(map[int64]runState, len(details))
+		for _, d := range details {
+			state := runState{status: d.run.Status, conclusion: d.run.Conclusion}
+			nextState[d.run.ID] = state
+			if prevState[d.run.ID] != state {
+				changed = true
+			}
+
+			if d.run.Status == shared.Completed {
+				delete(tracked, d.run.ID)
+				delete(lastDetail, d.run.ID)
+				delete(failures, d.run.ID)
+
+				if d.run.Conclusion != shared.Success {
+					anyFailed = true
+				}
+				fmt.Fprintln(out, shared.RenderRunHeader(cs, *d.run, "", d.prNumber))
+
+				if opts.Notifier != nil {
+					if err := opts.Notifier.Notify(d.run); err != nil {
+						fmt.Fprintf(opts.IO.ErrOut, "%s %s\n", cs.WarningIcon(), err)
+					}
+				}
+			}
+		}
+		prevState = nextState
+
+		active := activeOnly(details)
+		tableLines = renderDashboardTable(out, cs, active, opts.Now())
+
+		if len(active) == 0 && len(tracked) == 0 {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			restoreTerminal(opts.IO)
+			return cmdutil.SilentError
+		case <-time.After(poller.Next(changed)):
+		}
+	}
+
+	restoreTerminal(opts.IO)
+
+	if opts.ExitStatus && anyFailed {
+		return cmdutil.SilentError
+	}
+	return nil
+}
+
+func activeOnly(details []*runDetail) []*runDetail {
+	active := make([]*runDetail, 0, len(details))
+	for _, d := range details {
+		if d.run.Status != shared.Completed {
+			active = append(active, d)
+		}
+	}
+	return active
+}
+
+// fetchRunDetails fetches the full run, its jobs, and its PR number for
+// each run ID concurrently, bounded by dashboardConcurrency. Fetching by ID
+// (rather than reusing the summaries from the list call) is what lets a
+// tracked run still be picked up once it's Completed, even though the list
+// call's own filter excludes completed runs. A run whose fetch fails is
+// logged to ios.ErrOut and simply absent from the returned map — it's the
+// caller's job to decide whether a missing ID means "gone" or "try again
+// next tick", since this function has no way to tell a real 404 apart
+// from a transient network blip.
+func fetchRunDetails(ios *iostreams.IOStreams, client *api.Client, repo ghrepo.Interface, ids []int64) map[int64]*runDetail {
+	details := make([]*runDetail, len(ids))
+
+	sem := make(chan struct{}, dashboardConcurrency)
+	var wg sync.WaitGroup
+
+	for i := range ids {
+		i := i
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			run, err := shared.GetRun(client, repo, fmt.Sprintf("%d", ids[i]))
+			if err != nil {
+				fmt.Fprintf(ios.ErrOut, "%s failed to get run %d: %s\n", ios.ColorScheme().WarningIcon(), ids[i], err)
+				return
+			}
+
+			jobs, err := shared.GetJobs(client, repo, *run)
+			if err != nil {
+				fmt.Fprintf(ios.ErrOut, "%s failed to get jobs for run %d: %s\n", ios.ColorScheme().WarningIcon(), run.ID, err)
+				return
+			}
+
+			prNumber := ""
+			if number, err := shared.PullRequestForRun(client, repo, *run); err == nil {
+				prNumber = fmt.Sprintf(" #%d", number)
+			}
+
+			details[i] = &runDetail{run: run, prNumber: prNumber, lastJob: lastUpdatedJob(jobs)}
+		}()
+	}
+
+	wg.Wait()
+
+	result := make(map[int64]*runDetail, len(details))
+	for _, d := range details {
+		if d != nil {
+			result[d.run.ID] = d
+		}
+	}
+	return result
+}
+
+func lastUpdatedJob(jobs []shared.Job) string {
+	if len(jobs) == 0 {
+		return ""
+	}
+	job := jobs[len(jobs)-1]
+	for _, j := range jobs {
+		if j.Status == shared.InProgress {
+			job = j
+		}
+	}
+	return job.Name
+}
+
+// renderDashboardTable prints one line per active run and returns how
+// many lines it wrote, so the caller knows how far to rewind next tick.
+func renderDashboardTable(out io.Writer, cs *iostreams.ColorScheme, details []*runDetail, now time.Time) int {
+	fmt.Fprintln(out, cs.Boldf("Watching %d run(s). Press Ctrl+C to quit.", len(details)))
+	for _, d := range details {
+		run := d.run
+		elapsed := utils.FuzzyAgo(now.Sub(run.CreatedAt))
+		fmt.Fprintf(out, "%s %-30.30s %-20.20s%-8s %6s  %s\n",
+			runGlyph(cs, *run), run.Name, run.HeadBranch, d.prNumber, elapsed, d.lastJob)
+	}
+	return len(details) + 1
+}
+
+func runGlyph(cs *iostreams.ColorScheme, run shared.Run) string {
+	switch run.Status {
+	case shared.Completed:
+		if run.Conclusion == shared.Success {
+			return cs.SuccessIcon()
+		}
+		return cs.FailureIcon()
+	case shared.InProgress:
+		return cs.Yellow("●")
+	default:
+		return cs.Gray("○")
+	}
+}
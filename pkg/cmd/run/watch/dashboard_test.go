@@ -0,0 +1,225 @@
+package watch
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cli/cli/api"
+	"github.com/cli/cli/internal/ghrepo"
+	"github.com/cli/cli/pkg/cmd/run/shared"
+	"github.com/cli/cli/pkg/cmdutil"
+	"github.com/cli/cli/pkg/iostreams"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLastUpdatedJob_PrefersInProgress(t *testing.T) {
+	jobs := []shared.Job{
+		{Name: "build", Status: shared.Completed},
+		{Name: "test", Status: shared.InProgress},
+		{Name: "lint", Status: shared.Queued},
+	}
+	assert.Equal(t, "test", lastUpdatedJob(jobs))
+}
+
+func TestLastUpdatedJob_FallsBackToLast(t *testing.T) {
+	jobs := []shared.Job{
+		{Name: "build", Status: shared.Completed},
+		{Name: "test", Status: shared.Completed},
+	}
+	assert.Equal(t, "test", lastUpdatedJob(jobs))
+}
+
+func TestLastUpdatedJob_Empty(t *testing.T) {
+	assert.Equal(t, "", lastUpdatedJob(nil))
+}
+
+func TestActiveOnly_DropsCompletedRuns(t *testing.T) {
+	details := []*runDetail{
+		{run: &shared.Run{ID: 1, Status: shared.InProgress}},
+		{run: &shared.Run{ID: 2, Status: shared.Completed}},
+		{run: &shared.Run{ID: 3, Status: shared.Queued}},
+	}
+
+	active := activeOnly(details)
+
+	require := assert.New(t)
+	require.Len(active, 2)
+	require.Equal(int64(1), active[0].run.ID)
+	require.Equal(int64(3), active[1].run.ID)
+}
+
+// fakeNotifier records every run it's asked to notify about, so a test can
+// assert completion hooks actually fired instead of just checking the
+// rendered table.
+type fakeNotifier struct {
+	notified []*shared.Run
+}
+
+func (f *fakeNotifier) Notify(run *shared.Run) error {
+	f.notified = append(f.notified, run)
+	return nil
+}
+
+// dashboardFakeTransport points every request at srv regardless of the
+// scheme/host the caller built the request with, so the test can drive
+// real api.Client/ghrepo plumbing without actually hitting a host.
+type dashboardFakeTransport struct {
+	srv *httptest.Server
+}
+
+func (t *dashboardFakeTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	target, err := url.Parse(t.srv.URL)
+	if err != nil {
+		return nil, err
+	}
+	req.URL.Scheme = target.Scheme
+	req.URL.Host = target.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// TestWatchDashboard_CompletesRunDroppedFromList drives watchDashboard
+// against a fake server where run 1 is reported in_progress by the list
+// call on the first tick, then (simulating the list's own completed-run
+// filter kicking in before our per-ID refetch does) disappears from the
+// list entirely on the second tick while still being Completed when
+// fetched directly by ID. The dashboard must still print its summary,
+// notify, and fail --exit-status instead of just letting the run vanish.
+func TestWatchDashboard_CompletesRunDroppedFromList(t *testing.T) {
+	var listHits, runHits int32
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/OWNER/REPO/actions/runs", func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&listHits, 1) == 1 {
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"total_count": 1,
+				"workflow_runs": []map[string]interface{}{
+					{"id": 1, "name": "build", "head_branch": "main", "status": "in_progress"},
+				},
+			})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"total_count": 0, "workflow_runs": []map[string]interface{}{}})
+	})
+	mux.HandleFunc("/repos/OWNER/REPO/actions/runs/1", func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/jobs") {
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"total_count": 0, "jobs": []map[string]interface{}{}})
+			return
+		}
+		hit := atomic.AddInt32(&runHits, 1)
+		status := "in_progress"
+		conclusion := ""
+		if hit >= 2 {
+			status = "completed"
+			conclusion = "failure"
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"id": 1, "name": "build", "head_branch": "main",
+			"status": status, "conclusion": conclusion,
+		})
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	httpClient := &http.Client{Transport: &dashboardFakeTransport{srv: srv}}
+	client := api.NewClientFromHTTP(httpClient)
+	repo := ghrepo.New("OWNER", "REPO")
+
+	io, _, _, _ := iostreams.Test()
+	notifier := &fakeNotifier{}
+	opts := &WatchOptions{
+		IO:         io,
+		Interval:   0,
+		ExitStatus: true,
+		Now:        time.Now,
+		Notifier:   notifier,
+	}
+
+	err := watchDashboard(context.Background(), opts, client, repo, func(shared.Run) bool { return true })
+
+	assert.Equal(t, cmdutil.SilentError, err, "--exit-status should surface the failed run's conclusion")
+	assert.GreaterOrEqual(t, int(atomic.LoadInt32(&listHits)), 2, "the dashboard should have polled the list more than once")
+
+	require.Len(t, notifier.notified, 1)
+	assert.Equal(t, int64(1), notifier.notified[0].ID)
+	assert.Equal(t, shared.Failure, notifier.notified[0].Conclusion)
+}
+
+// TestWatchDashboard_TransientFetchErrorDoesNotEndWatch drives watchDashboard
+// against a fake server where the very first by-ID refetch of the only
+// tracked run fails outright (a simulated network blip). The run must stay
+// tracked off the back of its last-known detail rather than quietly
+// dropping out of "active", which would otherwise make the dashboard treat
+// one bad GET as "every matched run has completed" and exit successfully
+// while the run is still going.
+func TestWatchDashboard_TransientFetchErrorDoesNotEndWatch(t *testing.T) {
+	var runHits int32
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/OWNER/REPO/actions/runs", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"total_count": 1,
+			"workflow_runs": []map[string]interface{}{
+				{"id": 1, "name": "build", "head_branch": "main", "status": "in_progress"},
+			},
+		})
+	})
+	mux.HandleFunc("/repos/OWNER/REPO/actions/runs/1", func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/jobs") {
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"total_count": 0, "jobs": []map[string]interface{}{}})
+			return
+		}
+		hit := atomic.AddInt32(&runHits, 1)
+		if hit == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		status := "in_progress"
+		conclusion := ""
+		if hit >= 3 {
+			status = "completed"
+			conclusion = "success"
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"id": 1, "name": "build", "head_branch": "main",
+			"status": status, "conclusion": conclusion,
+		})
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	httpClient := &http.Client{Transport: &dashboardFakeTransport{srv: srv}}
+	client := api.NewClientFromHTTP(httpClient)
+	repo := ghrepo.New("OWNER", "REPO")
+
+	io, _, _, _ := iostreams.Test()
+	notifier := &fakeNotifier{}
+	opts := &WatchOptions{
+		IO:       io,
+		Interval: 0,
+		Now:      time.Now,
+		Notifier: notifier,
+	}
+
+	err := watchDashboard(context.Background(), opts, client, repo, func(shared.Run) bool { return true })
+
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, int(atomic.LoadInt32(&runHits)), 3, "the dashboard should have retried the failed run past the first bad fetch")
+	require.Len(t, notifier.notified, 1, "the run's eventual completion should still be observed and notified despite the earlier fetch error")
+	assert.Equal(t, shared.Success, notifier.notified[0].Conclusion)
+}
@@ -0,0 +1,106 @@
+package watch
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/cli/cli/pkg/cmd/run/shared"
+	"github.com/cli/cli/pkg/iostreams"
+)
+
+// Notifier is called once a watched run transitions to Completed. It's an
+// interface rather than a concrete function so tests can stub out
+// anything that would otherwise shell out or hit the terminal bell.
+type Notifier interface {
+	Notify(run *shared.Run) error
+}
+
+// completionNotifier is the default Notifier: it fires an OS desktop
+// notification, optionally runs a user-supplied command, and optionally
+// rings the terminal bell.
+type completionNotifier struct {
+	io         *iostreams.IOStreams
+	onComplete string
+	bell       bool
+}
+
+func NewNotifier(io *iostreams.IOStreams, onComplete string, bell bool) Notifier {
+	return &completionNotifier{io: io, onComplete: onComplete, bell: bell}
+}
+
+func (n *completionNotifier) Notify(run *shared.Run) error {
+	var errs []string
+
+	if err := desktopNotify(run); err != nil {
+		errs = append(errs, err.Error())
+	}
+
+	if n.onComplete != "" {
+		if err := runOnComplete(n.onComplete, run); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+
+	if n.bell && n.io.IsStdoutTTY() {
+		fmt.Fprint(n.io.Out, "\a")
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("notification failed: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+func runOnComplete(command string, run *shared.Run) error {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", command)
+	} else {
+		cmd = exec.Command("sh", "-c", command)
+	}
+	cmd.Env = append(os.Environ(),
+		fmt.Sprintf("GH_RUN_ID=%d", run.ID),
+		fmt.Sprintf("GH_RUN_CONCLUSION=%s", run.Conclusion),
+		fmt.Sprintf("GH_RUN_URL=%s", run.URL),
+	)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// desktopNotify fires a native desktop notification using whatever the
+// platform gives us, picking the first mechanism found on PATH. It's a
+// best-effort affair: an unsupported platform, or one missing every tool
+// we know about, is not an error worth failing the whole notify step over.
+func desktopNotify(run *shared.Run) error {
+	title := "GitHub Actions"
+	message := fmt.Sprintf("Run #%d %s", run.ID, strings.ToLower(string(run.Conclusion)))
+
+	switch runtime.GOOS {
+	case "darwin":
+		if _, err := exec.LookPath("osascript"); err != nil {
+			return nil
+		}
+		script := fmt.Sprintf("display notification %q with title %q", message, title)
+		return exec.Command("osascript", "-e", script).Run()
+	case "linux":
+		if _, err := exec.LookPath("notify-send"); err != nil {
+			return nil
+		}
+		return exec.Command("notify-send", title, message).Run()
+	case "windows":
+		if _, err := exec.LookPath("BurntToast"); err == nil {
+			ps := fmt.Sprintf("New-BurntToastNotification -Text %q, %q", title, message)
+			return exec.Command("powershell", "-NoProfile", "-Command", ps).Run()
+		}
+		if _, err := exec.LookPath("msg"); err != nil {
+			return nil
+		}
+		return exec.Command("msg", "*", fmt.Sprintf("%s: %s", title, message)).Run()
+	default:
+		return nil
+	}
+}
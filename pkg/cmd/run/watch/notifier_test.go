@@ -0,0 +1,33 @@
+package watch
+
+import (
+	"io/ioutil"
+	"runtime"
+	"testing"
+
+	"github.com/cli/cli/pkg/cmd/run/shared"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunOnComplete_SetsEnv(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("this test exercises the sh -c branch, not the windows one")
+	}
+
+	out, err := ioutil.TempFile("", "gh-run-watch-test")
+	require.NoError(t, err)
+	defer out.Close()
+
+	run := &shared.Run{ID: 42, Conclusion: shared.Failure, URL: "https://github.com/OWNER/REPO/actions/runs/42"}
+
+	err = runOnComplete("env > "+out.Name(), run)
+	require.NoError(t, err)
+
+	env, err := ioutil.ReadFile(out.Name())
+	require.NoError(t, err)
+
+	assert.Contains(t, string(env), "GH_RUN_ID=42")
+	assert.Contains(t, string(env), "GH_RUN_CONCLUSION=failure")
+	assert.Contains(t, string(env), "GH_RUN_URL=https://github.com/OWNER/REPO/actions/runs/42")
+}
@@ -1,10 +1,14 @@
 package watch
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
+	"os"
+	"os/signal"
 	"runtime"
+	"syscall"
 	"time"
 
 	"github.com/cli/cli/api"
@@ -16,6 +20,19 @@ import (
 	"github.com/spf13/cobra"
 )
 
+// hammerTimeWindow is how long a user has to hit Ctrl+C a second time
+// before we fall back to treating the watch as still running gracefully.
+const hammerTimeWindow = 3 * time.Second
+
+const (
+	// maxPollInterval caps how far the adaptive poller will back off when
+	// a run sits unchanged for a while.
+	maxPollInterval = 30 * time.Second
+	// staleTicksBeforeBackoff is how many unchanged polls we tolerate at
+	// the requested interval before backing off.
+	staleTicksBeforeBackoff = 3
+)
+
 type WatchOptions struct {
 	IO         *iostreams.IOStreams
 	HttpClient func() (*http.Client, error)
@@ -24,10 +41,18 @@ type WatchOptions struct {
 	RunID      string
 	Interval   int
 	ExitStatus bool
+	Logs       bool
+	OnComplete string
+	Bell       bool
+
+	All          bool
+	WorkflowName string
+	Branch       string
 
 	Prompt bool
 
-	Now func() time.Time
+	Now      func() time.Time
+	Notifier Notifier
 }
 
 func NewCmdWatch(f *cmdutil.Factory, runF func(*WatchOptions) error) *cobra.Command {
@@ -35,10 +60,13 @@ func NewCmdWatch(f *cmdutil.Factory, runF func(*WatchOptions) error) *cobra.Comm
 		IO:         f.IOStreams,
 		HttpClient: f.HttpClient,
 		Now:        time.Now,
+		Bell:       true,
 	}
 
+	var noBell bool
+
 	cmd := &cobra.Command{
-		Use:   "watch <run-selector>",
+		Use:   "watch [<run-selector>]",
 		Short: "Runs until a run completes, showing its progress",
 		Annotations: map[string]string{
 			"IsActions": "true",
@@ -47,8 +75,20 @@ func NewCmdWatch(f *cmdutil.Factory, runF func(*WatchOptions) error) *cobra.Comm
 			// support `-R, --repo` override
 			opts.BaseRepo = f.BaseRepo
 
+			if noBell {
+				opts.Bell = false
+			}
+			opts.Notifier = NewNotifier(opts.IO, opts.OnComplete, opts.Bell)
+
+			dashboard := opts.All || opts.WorkflowName != "" || opts.Branch != ""
+
 			if len(args) > 0 {
+				if dashboard {
+					return &cmdutil.FlagError{Err: errors.New("cannot specify a run ID with --all, --workflow, or --branch")}
+				}
 				opts.RunID = args[0]
+			} else if dashboard {
+				// watching every matching run; no single run to prompt for
 			} else if !opts.IO.CanPrompt() {
 				return &cmdutil.FlagError{Err: errors.New("run ID required when not running interactively")}
 			} else {
@@ -59,20 +99,31 @@ func NewCmdWatch(f *cmdutil.Factory, runF func(*WatchOptions) error) *cobra.Comm
 				return runF(opts)
 			}
 
-			return watchRun(opts)
+			return watchRun(cmd.Context(), opts)
 		},
 	}
 	cmd.Flags().BoolVar(&opts.ExitStatus, "exit-status", false, "Exit with non-zero status if run fails")
 	cmd.Flags().IntVarP(&opts.Interval, "interval", "i", 2, "Refresh interval in seconds")
+	cmd.Flags().BoolVar(&opts.Logs, "logs", false, "Tail the logs of the currently running job")
+	cmd.Flags().StringVar(&opts.OnComplete, "on-complete", "", "Run a shell command once the run completes")
+	cmd.Flags().BoolVar(&noBell, "no-bell", false, "Don't ring the terminal bell when the run completes")
+	cmd.Flags().BoolVar(&opts.All, "all", false, "Watch all in-progress runs instead of a single run")
+	cmd.Flags().StringVar(&opts.WorkflowName, "workflow", "", "Limit the dashboard to runs of a workflow")
+	cmd.Flags().StringVar(&opts.Branch, "branch", "", "Limit the dashboard to runs on a branch")
 
 	return cmd
 }
 
-func watchRun(opts *WatchOptions) error {
+func watchRun(ctx context.Context, opts *WatchOptions) error {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go hammerTime(opts.IO)
+
 	c, err := opts.HttpClient()
 	if err != nil {
 		return fmt.Errorf("failed to create http client: %w", err)
 	}
+	c.Transport = shared.NewConditionalCacheTransport(c.Transport)
 	client := api.NewClientFromHTTP(c)
 
 	repo, err := opts.BaseRepo()
@@ -80,6 +131,18 @@ func watchRun(opts *WatchOptions) error {
 		return fmt.Errorf("failed to determine base repo: %w", err)
 	}
 
+	if opts.All || opts.WorkflowName != "" || opts.Branch != "" {
+		return watchDashboard(ctx, opts, client, repo, func(run shared.Run) bool {
+			if opts.WorkflowName != "" && run.Name != opts.WorkflowName {
+				return false
+			}
+			if opts.Branch != "" && run.HeadBranch != opts.Branch {
+				return false
+			}
+			return true
+		})
+	}
+
 	runID := opts.RunID
 
 	if opts.Prompt {
@@ -114,18 +177,59 @@ func watchRun(opts *WatchOptions) error {
 		prNumber = fmt.Sprintf(" #%d", number)
 	}
 
-	if runtime.GOOS == "windows" {
-		opts.IO.EnableVirtualTerminalProcessing()
+	setupScreen(opts)
+
+	poller := shared.NewAdaptivePoller(time.Duration(opts.Interval)*time.Second, maxPollInterval, staleTicksBeforeBackoff)
+
+	var tailer *shared.LogTailer
+	if opts.Logs {
+		tailer = shared.NewLogTailer(logTailWindow(opts.IO))
 	}
-	// clear entire screen
-	fmt.Fprintf(opts.IO.Out, "\x1b[2J")
+	var tailingJobID int64
+
+	prevJobs := map[int64]jobState{}
+
+	// interval is the cadence renderRun reports in its banner. It starts at
+	// the requested --interval and is kept in sync with whatever the
+	// adaptive poller actually waits below, so a backed-off or snapped-back
+	// poll never gets announced as if it were still running at the
+	// originally requested rate.
+	interval := time.Duration(opts.Interval) * time.Second
 
 	for run.Status != shared.Completed {
-		run, err = renderRun(*opts, client, repo, run, prNumber)
+		prevStatus, prevConclusion := run.Status, run.Conclusion
+
+		var jobs []shared.Job
+		run, jobs, err = renderRun(*opts, client, repo, run, prNumber, interval, renderLogsState{
+			client: c,
+			tailer: tailer,
+			jobID:  &tailingJobID,
+		})
 		if err != nil {
+			restoreTerminal(opts.IO)
 			return err
 		}
-		time.Sleep(time.Duration(opts.Interval * 1000))
+
+		var jobsChanged bool
+		prevJobs, jobsChanged = diffJobs(prevJobs, jobs)
+
+		changed := run.Status != prevStatus || run.Conclusion != prevConclusion || jobsChanged
+
+		interval = poller.Next(changed)
+		select {
+		case <-ctx.Done():
+			restoreTerminal(opts.IO)
+			return cmdutil.SilentError
+		case <-time.After(interval):
+		}
+	}
+
+	restoreTerminal(opts.IO)
+
+	if opts.Notifier != nil {
+		if err := opts.Notifier.Notify(run); err != nil {
+			fmt.Fprintf(opts.IO.ErrOut, "%s %s\n", opts.IO.ColorScheme().WarningIcon(), err)
+		}
 	}
 
 	if opts.ExitStatus && run.Conclusion != shared.Success {
@@ -135,7 +239,94 @@ func watchRun(opts *WatchOptions) error {
 	return nil
 }
 
-func renderRun(opts WatchOptions, client *api.Client, repo ghrepo.Interface, run *shared.Run, prNumber string) (*shared.Run, error) {
+// hammerTime gives an impatient user a way out: the first Ctrl+C is caught
+// by the context created in watchRun and lets the loop above exit on its
+// own terms, but if a second one lands within hammerTimeWindow we stop
+// waiting for that cooperative exit and restore the terminal ourselves.
+func hammerTime(io *iostreams.IOStreams) {
+	sigCh := make(chan os.Signal, 2)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sigCh)
+
+	<-sigCh
+	select {
+	case <-sigCh:
+		restoreTerminal(io)
+		os.Exit(130)
+	case <-time.After(hammerTimeWindow):
+	}
+}
+
+// setupScreen prepares the terminal for repeated redraws: it turns on VT
+// processing on Windows (so the ANSI sequences below actually do
+// something) and clears whatever was on screen before we started.
+func setupScreen(opts *WatchOptions) {
+	if runtime.GOOS == "windows" {
+		opts.IO.EnableVirtualTerminalProcessing()
+	}
+	fmt.Fprintf(opts.IO.Out, "\x1b[2J")
+}
+
+// restoreTerminal makes sure the cursor is visible on the way out. renderRun
+// itself never hides the cursor or sets a scroll region (every tick redraws
+// the whole screen from 0,0), so showing the cursor is all this undoes.
+func restoreTerminal(io *iostreams.IOStreams) {
+	fmt.Fprint(io.Out, "\x1b[?25h") // show cursor
+}
+
+// jobState is the bit of a job's identity we diff tick-to-tick so the
+// poller can snap back to opts.Interval as soon as any job transitions,
+// even when that doesn't change the run's own overall status.
+type jobState struct {
+	status     shared.Status
+	conclusion shared.Conclusion
+}
+
+// diffJobs compares this tick's jobs against the jobState map from the
+// previous tick and reports whether any job's status or conclusion moved,
+// so watchRun's poller can snap back to opts.Interval on a per-job
+// transition even when that doesn't change the run's own overall status.
+func diffJobs(prev map[int64]jobState, jobs []shared.Job) (map[int64]jobState, bool) {
+	next := make(map[int64]jobState, len(jobs))
+	changed := false
+	for _, job := range jobs {
+		state := jobState{status: job.Status, conclusion: job.Conclusion}
+		next[job.ID] = state
+		if prev[job.ID] != state {
+			changed = true
+		}
+	}
+	return next, changed
+}
+
+// renderLogsState threads the bits renderRun needs to tail the active
+// job's logs across ticks: the raw http client (log endpoints aren't
+// JSON, so they bypass api.Client), the tailer tracking byte offsets per
+// job, and which job we were tailing last tick.
+type renderLogsState struct {
+	client *http.Client
+	tailer *shared.LogTailer
+	jobID  *int64
+}
+
+// defaultLogTailLines is how many trailing lines of the active job's log
+// we show beneath the JOBS table, and the largest window logTailWindow
+// returns.
+const defaultLogTailLines = 10
+
+// logTailWindow sizes the tail window to a third of the terminal's height,
+// capped at defaultLogTailLines. When a third of the height is under 3
+// lines (including a reported height of 0), it returns defaultLogTailLines,
+// so on a very short terminal the log pane can take more than a third.
+func logTailWindow(io *iostreams.IOStreams) int {
+	_, height := io.TerminalSize()
+	if window := height / 3; window >= 3 && window < defaultLogTailLines {
+		return window
+	}
+	return defaultLogTailLines
+}
+
+func renderRun(opts WatchOptions, client *api.Client, repo ghrepo.Interface, run *shared.Run, prNumber string, interval time.Duration, logs renderLogsState) (*shared.Run, []shared.Job, error) {
 	out := opts.IO.Out
 	cs := opts.IO.ColorScheme()
 
@@ -143,14 +334,14 @@ func renderRun(opts WatchOptions, client *api.Client, repo ghrepo.Interface, run
 
 	run, err = shared.GetRun(client, repo, fmt.Sprintf("%d", run.ID))
 	if err != nil {
-		return run, fmt.Errorf("failed to get run: %w", err)
+		return run, nil, fmt.Errorf("failed to get run: %w", err)
 	}
 
 	ago := opts.Now().Sub(run.CreatedAt)
 
 	jobs, err := shared.GetJobs(client, repo, *run)
 	if err != nil {
-		return run, fmt.Errorf("failed to get jobs: %w", err)
+		return run, nil, fmt.Errorf("failed to get jobs: %w", err)
 	}
 
 	var annotations []shared.Annotation
@@ -166,7 +357,7 @@ func renderRun(opts WatchOptions, client *api.Client, repo ghrepo.Interface, run
 	}
 
 	if annotationErr != nil {
-		return run, fmt.Errorf("failed to get annotations: %w", annotationErr)
+		return run, jobs, fmt.Errorf("failed to get annotations: %w", annotationErr)
 	}
 
 	if runtime.GOOS == "windows" {
@@ -179,24 +370,76 @@ func renderRun(opts WatchOptions, client *api.Client, repo ghrepo.Interface, run
 		fmt.Fprint(opts.IO.Out, "\x1b[J")
 	}
 
-	fmt.Fprintln(out, cs.Boldf("Refreshing run status every %d seconds. Press Ctrl+C to quit.", opts.Interval))
+	fmt.Fprintln(out, cs.Boldf("Refreshing run status every %.0f seconds. Press Ctrl+C to quit.", interval.Seconds()))
 	fmt.Fprintln(out)
 	fmt.Fprintln(out, shared.RenderRunHeader(cs, *run, utils.FuzzyAgo(ago), prNumber))
 	fmt.Fprintln(out)
 
 	if len(jobs) == 0 && run.Conclusion == shared.Failure {
-		return run, nil
+		return run, jobs, nil
 	}
 
 	fmt.Fprintln(out, cs.Bold("JOBS"))
 
 	fmt.Fprintln(out, shared.RenderJobs(cs, jobs, true))
 
+	if opts.Logs && logs.tailer != nil {
+		renderLogTail(opts, repo, jobs, logs)
+	}
+
 	if len(annotations) > 0 {
 		fmt.Fprintln(out)
 		fmt.Fprintln(out, cs.Bold("ANNOTATIONS"))
 		fmt.Fprintln(out, shared.RenderAnnotations(cs, annotations))
 	}
 
-	return run, nil
+	return run, jobs, nil
+}
+
+// renderLogTail prints a trailing window of the currently in-progress
+// job's log beneath the JOBS table. When the in-progress job changes
+// (the previous one finished, a new one started), it collapses the old
+// tail and starts fresh against the new job.
+func renderLogTail(opts WatchOptions, repo ghrepo.Interface, jobs []shared.Job, logs renderLogsState) {
+	out := opts.IO.Out
+	cs := opts.IO.ColorScheme()
+
+	var running *shared.Job
+	for i := range jobs {
+		if jobs[i].Status == shared.InProgress {
+			running = &jobs[i]
+			break
+		}
+	}
+
+	if running == nil {
+		if *logs.jobID != 0 {
+			logs.tailer.Forget(*logs.jobID)
+			*logs.jobID = 0
+		}
+		return
+	}
+
+	if *logs.jobID != running.ID {
+		if *logs.jobID != 0 {
+			logs.tailer.Forget(*logs.jobID)
+		}
+		*logs.jobID = running.ID
+	}
+
+	lines, err := logs.tailer.Tail(logs.client, repo, *running)
+	if err != nil {
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, cs.Gray(fmt.Sprintf("(failed to fetch logs for %s: %s)", running.Name, err)))
+		return
+	}
+	if len(lines) == 0 {
+		return
+	}
+
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, cs.Boldf("LOGS (%s)", running.Name))
+	for _, line := range lines {
+		fmt.Fprintln(out, line)
+	}
 }
@@ -0,0 +1,152 @@
+package watch
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cli/cli/internal/ghrepo"
+	"github.com/cli/cli/pkg/cmd/run/shared"
+	"github.com/cli/cli/pkg/iostreams"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffJobs_NoChangeWhenStable(t *testing.T) {
+	prev, changed := diffJobs(nil, []shared.Job{
+		{ID: 1, Status: shared.InProgress},
+	})
+	require.True(t, changed, "the very first tick always reports a change")
+
+	_, changed = diffJobs(prev, []shared.Job{
+		{ID: 1, Status: shared.InProgress},
+	})
+	assert.False(t, changed, "an unchanged job shouldn't be reported as a change")
+}
+
+func TestDiffJobs_DetectsPerJobTransition(t *testing.T) {
+	prev, _ := diffJobs(nil, []shared.Job{
+		{ID: 1, Status: shared.InProgress},
+		{ID: 2, Status: shared.Queued},
+	})
+
+	next, changed := diffJobs(prev, []shared.Job{
+		{ID: 1, Status: shared.InProgress},
+		{ID: 2, Status: shared.Completed, Conclusion: shared.Success},
+	})
+
+	assert.True(t, changed, "job 2 finishing should be reported as a change even though job 1 didn't move")
+	assert.Equal(t, jobState{status: shared.Completed, conclusion: shared.Success}, next[2])
+}
+
+func TestDiffJobs_DetectsNewJobAppearing(t *testing.T) {
+	prev, _ := diffJobs(nil, []shared.Job{
+		{ID: 1, Status: shared.InProgress},
+	})
+
+	_, changed := diffJobs(prev, []shared.Job{
+		{ID: 1, Status: shared.InProgress},
+		{ID: 2, Status: shared.Queued},
+	})
+
+	assert.True(t, changed, "a newly appeared job should count as a change")
+}
+
+// logTailFakeTransport serves /actions/jobs/<id>/logs for however many job
+// IDs the test registers, tracking the Range header each job ID was asked
+// for so a test can tell whether a tail resumed from a remembered offset
+// or restarted from scratch.
+type logTailFakeTransport struct {
+	ranges map[int64][]string
+	body   map[int64]string
+}
+
+func (t *logTailFakeTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var jobID int64
+	_, _ = fmt.Sscanf(req.URL.Path, "/repos/OWNER/REPO/actions/jobs/%d/logs", &jobID)
+
+	t.ranges[jobID] = append(t.ranges[jobID], req.Header.Get("Range"))
+
+	rec := httptest.NewRecorder()
+	if strings.HasPrefix(req.Header.Get("Range"), "bytes=0-") {
+		_, _ = rec.WriteString(t.body[jobID])
+	} else {
+		rec.WriteHeader(http.StatusRequestedRangeNotSatisfiable)
+	}
+	return rec.Result(), nil
+}
+
+func TestRenderLogTail_SwitchesJobAndForgetsPrevious(t *testing.T) {
+	transport := &logTailFakeTransport{
+		ranges: map[int64][]string{},
+		body: map[int64]string{
+			1: "2021-01-01T00:00:00.0000000Z job one line\n",
+			2: "2021-01-01T00:00:00.0000000Z job two line\n",
+		},
+	}
+	httpClient := &http.Client{Transport: transport}
+	repo := ghrepo.New("OWNER", "REPO")
+
+	io, _, stdout, _ := iostreams.Test()
+	opts := WatchOptions{IO: io}
+
+	tailer := shared.NewLogTailer(10)
+	var tailingJobID int64
+	logs := renderLogsState{client: httpClient, tailer: tailer, jobID: &tailingJobID}
+
+	renderLogTail(opts, repo, []shared.Job{
+		{ID: 1, Name: "build", Status: shared.InProgress},
+	}, logs)
+	assert.Equal(t, int64(1), tailingJobID)
+	assert.Contains(t, stdout.String(), "job one line")
+
+	stdout.Reset()
+	renderLogTail(opts, repo, []shared.Job{
+		{ID: 1, Name: "build", Status: shared.Completed},
+		{ID: 2, Name: "test", Status: shared.InProgress},
+	}, logs)
+
+	assert.Equal(t, int64(2), tailingJobID, "the tailer should switch to the new in-progress job")
+	assert.Contains(t, stdout.String(), "job two line")
+
+	require.Len(t, transport.ranges[1], 1, "job 1 should only have been fetched once, before it was forgotten")
+
+	// Job 1 becomes the running job again; since it was Forgotten when we
+	// switched away, its tail must restart from byte 0 rather than resume
+	// from wherever it left off.
+	stdout.Reset()
+	renderLogTail(opts, repo, []shared.Job{
+		{ID: 1, Name: "build", Status: shared.InProgress},
+	}, logs)
+
+	require.Len(t, transport.ranges[1], 2)
+	assert.Equal(t, "bytes=0-", transport.ranges[1][1], "a re-tracked job should restart its tail from scratch")
+}
+
+func TestRenderLogTail_ForgetsJobWhenNothingIsRunning(t *testing.T) {
+	transport := &logTailFakeTransport{
+		ranges: map[int64][]string{},
+		body:   map[int64]string{1: "2021-01-01T00:00:00.0000000Z job one line\n"},
+	}
+	httpClient := &http.Client{Transport: transport}
+	repo := ghrepo.New("OWNER", "REPO")
+
+	io, _, _, _ := iostreams.Test()
+	opts := WatchOptions{IO: io}
+
+	tailer := shared.NewLogTailer(10)
+	var tailingJobID int64
+	logs := renderLogsState{client: httpClient, tailer: tailer, jobID: &tailingJobID}
+
+	renderLogTail(opts, repo, []shared.Job{
+		{ID: 1, Name: "build", Status: shared.InProgress},
+	}, logs)
+	assert.Equal(t, int64(1), tailingJobID)
+
+	renderLogTail(opts, repo, []shared.Job{
+		{ID: 1, Name: "build", Status: shared.Completed},
+	}, logs)
+	assert.Equal(t, int64(0), tailingJobID, "no job in progress should reset the tracked job ID")
+}